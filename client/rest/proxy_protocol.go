@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chassis/go-chassis/core/common"
+)
+
+// NewProxyProtocolTransport wraps base (nil means a zero-value
+// http.Transport) so that, when a request whose context carries a header
+// under common.ProxyProtocolContextKey causes a new connection to be
+// dialed, the header is written to the raw connection right after dialing,
+// before the request itself goes out. A request sent over a reused idle
+// connection writes no header of its own.
+func NewProxyProtocolTransport(base *http.Transport) *http.Transport {
+	if base == nil {
+		base = &http.Transport{}
+	}
+	t := base.Clone()
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if header, ok := ctx.Value(common.ProxyProtocolContextKey).([]byte); ok && len(header) > 0 {
+			if _, werr := conn.Write(header); werr != nil {
+				conn.Close()
+				return nil, werr
+			}
+		}
+		return conn, nil
+	}
+	return t
+}
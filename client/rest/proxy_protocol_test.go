@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-chassis/go-chassis/core/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyProtocolTransportWritesHeaderBeforeRequest(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, aerr := ln.Accept()
+		if aerr != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, len("PROXY TCP4 1.2.3.4 5.6.7.8 111 222\r\n"))
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _ := conn.Read(buf)
+		received <- buf[:n]
+	}()
+
+	transport := NewProxyProtocolTransport(nil)
+	header := []byte("PROXY TCP4 1.2.3.4 5.6.7.8 111 222\r\n")
+	ctx := context.WithValue(context.Background(), common.ProxyProtocolContextKey, header)
+
+	conn, err := transport.DialContext(ctx, "tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	select {
+	case got := <-received:
+		assert.Equal(t, header, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the PROXY protocol header")
+	}
+}
+
+func TestProxyProtocolTransportSkipsWriteWithoutHeader(t *testing.T) {
+	transport := NewProxyProtocolTransport(nil)
+	assert.NotNil(t, transport.DialContext)
+}
@@ -0,0 +1,16 @@
+package common
+
+// ClientAddrContextKey is where an inbound server handler may stash the
+// original client address ("host:port") so LBHandler can forward it via
+// PROXY protocol. It lives here, not in core/handler, so client/rest can
+// read it without an import cycle.
+type clientAddrContextKey struct{}
+
+var ClientAddrContextKey interface{} = clientAddrContextKey{}
+
+// ProxyProtocolContextKey is where LBHandler attaches the serialized PROXY
+// protocol header bytes so the rest client transport can prepend them on
+// the wire before writing the request.
+type proxyProtocolContextKey struct{}
+
+var ProxyProtocolContextKey interface{} = proxyProtocolContextKey{}
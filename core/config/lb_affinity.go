@@ -0,0 +1,56 @@
+package config
+
+import (
+	"github.com/go-chassis/go-chassis/core/archaius"
+	"github.com/go-chassis/go-chassis/core/loadbalancer"
+	"github.com/mitchellh/mapstructure"
+)
+
+// GetAffinityRules reads the affinity rule set configured for a given
+// source/destination pair under cse.loadbalancer.<src>.<dest>.affinity in
+// chassis.yaml, e.g.:
+//
+//	cse:
+//	  loadbalancer:
+//	    myConsumer:
+//	      myProvider:
+//	        affinity:
+//	          - key: zone
+//	            value: us-east-1a
+//	            weight: 50
+//	          - key: version
+//	            value: canary
+//	            weight: -100
+//	            required: false
+//
+// It returns nil, nil when no affinity rules are configured so callers can
+// fall back to the strategy named by GetStrategyName.
+func GetAffinityRules(src, dest string) ([]loadbalancer.AffinityRule, error) {
+	raw := archaius.Get(genMsKey("cse.loadbalancer", src, dest, "affinity"))
+	if raw == nil {
+		return nil, nil
+	}
+	var rules []loadbalancer.AffinityRule
+	if err := mapstructure.Decode(raw, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func genKey(s ...string) string {
+	key := ""
+	for i, p := range s {
+		if i > 0 {
+			key += "."
+		}
+		key += p
+	}
+	return key
+}
+
+func genMsKey(prefix, src, dest, property string) string {
+	if src == "" {
+		return genKey(prefix, dest, property)
+	}
+	return genKey(prefix, src, dest, property)
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"time"
+
+	"github.com/go-chassis/go-chassis/core/archaius"
+)
+
+// GetHedgeDelay reads cse.loadbalancer.<src>.<dest>.hedge.delay, the time a
+// caller waits for a response before firing a second, speculative request
+// against a different instance. A zero delay means hedging is disabled.
+func GetHedgeDelay(src, dest string) time.Duration {
+	return archaius.GetDuration(genMsKey("cse.loadbalancer", src, dest, "hedge.delay"), 0)
+}
+
+// GetHedgeMaxAttempts reads cse.loadbalancer.<src>.<dest>.hedge.maxAttempts,
+// the most in-flight copies of a single call hedging is allowed to run,
+// including the original. Defaults to 2 (one hedge) once hedging is enabled.
+func GetHedgeMaxAttempts(src, dest string) int {
+	return archaius.GetInt(genMsKey("cse.loadbalancer", src, dest, "hedge.maxAttempts"), 2)
+}
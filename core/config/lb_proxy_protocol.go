@@ -0,0 +1,11 @@
+package config
+
+import "github.com/go-chassis/go-chassis/core/archaius"
+
+// GetProxyProtocol reads cse.loadbalancer.<src>.<dest>.proxyProtocol, one of
+// "send-proxy", "send-proxy-v2", or "off" (the default). It controls
+// whether LBHandler prepends a PROXY protocol v1/v2 header carrying the
+// original client address when forwarding to the picked endpoint.
+func GetProxyProtocol(src, dest string) string {
+	return archaius.GetString(genMsKey("cse.loadbalancer", src, dest, "proxyProtocol"), "off")
+}
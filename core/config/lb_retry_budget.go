@@ -0,0 +1,20 @@
+package config
+
+import (
+	"time"
+
+	"github.com/go-chassis/go-chassis/core/archaius"
+)
+
+// GetRetryBudget reads the overall retry budget configured for a given
+// source/destination pair: an absolute wall-clock deadline
+// (cse.loadbalancer.<src>.<dest>.retry.budget) plus the fraction of
+// requests that may be retried (cse.loadbalancer.<src>.<dest>.retry.budgetRatio,
+// e.g. 0.1 lets roughly 10% of requests retry). A zero deadline means no
+// deadline is enforced; a zero or negative ratio disables the ratio limit,
+// leaving retries bounded by retryOnSame/retryOnNext and the deadline.
+func GetRetryBudget(src, dest string) (deadline time.Duration, ratio float64) {
+	deadline = archaius.GetDuration(genMsKey("cse.loadbalancer", src, dest, "retry.budget"), 0)
+	ratio = archaius.GetFloat64(genMsKey("cse.loadbalancer", src, dest, "retry.budgetRatio"), 0)
+	return
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"time"
+
+	"github.com/go-chassis/go-chassis/core/archaius"
+	"github.com/go-chassis/go-chassis/core/loadbalancer"
+)
+
+// SpreadConfig is the chassis.yaml shape for cse.loadbalancer.<src>.<dest>.spread.
+type SpreadConfig struct {
+	Attribute string             `yaml:"attribute"`
+	Targets   map[string]float64 `yaml:"targets"`
+	Window    time.Duration      `yaml:"window"`
+}
+
+// GetSpreadConfig reads the spread distribution configured for a given
+// source/destination pair, e.g.:
+//
+//	cse:
+//	  loadbalancer:
+//	    myConsumer:
+//	      myProvider:
+//	        spread:
+//	          attribute: datacenter
+//	          window: 30s
+//	          targets:
+//	            dc1: 0.6
+//	            dc2: 0.3
+//	            dc3: 0.1
+//
+// It returns ok=false when no spread attribute is configured so callers
+// fall back to the strategy named by GetStrategyName.
+func GetSpreadConfig(src, dest string) (cfg SpreadConfig, ok bool) {
+	attr := archaius.GetString(genMsKey("cse.loadbalancer", src, dest, "spread.attribute"), "")
+	if attr == "" {
+		return SpreadConfig{}, false
+	}
+	cfg.Attribute = attr
+	cfg.Window = archaius.GetDuration(genMsKey("cse.loadbalancer", src, dest, "spread.window"), 0)
+
+	raw := archaius.Get(genMsKey("cse.loadbalancer", src, dest, "spread.targets"))
+	if m, mok := raw.(map[string]interface{}); mok {
+		cfg.Targets = make(map[string]float64, len(m))
+		for k, v := range m {
+			if f, fok := v.(float64); fok {
+				cfg.Targets[k] = f
+			}
+		}
+	}
+	return cfg, true
+}
+
+// ToSpreadTarget converts chassis.yaml's map[string]float64 into the
+// loadbalancer package's SpreadTarget type.
+func (c SpreadConfig) ToSpreadTarget() loadbalancer.SpreadTarget {
+	return loadbalancer.SpreadTarget(c.Targets)
+}
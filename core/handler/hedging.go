@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-chassis/go-chassis/core/config"
+	"github.com/go-chassis/go-chassis/core/invocation"
+)
+
+// handleWithHedging issues a second, speculative chain.Next against a
+// different instance once the hedge delay elapses without a response, and
+// returns whichever attempt finishes first, canceling the rest.
+func (lb *LBHandler) handleWithHedging(chain *Chain, i *invocation.Invocation, cb invocation.ResponseCallBack) {
+	delay := config.GetHedgeDelay(i.SourceMicroService, i.MicroServiceName)
+	maxAttempts := config.GetHedgeMaxAttempts(i.SourceMicroService, i.MicroServiceName)
+	if maxAttempts < 2 {
+		maxAttempts = 2
+	}
+	handlerIndex := chain.HandlerIndex
+
+	var once sync.Once
+	var mu sync.Mutex
+	exclude := make(map[string]struct{})
+	cancels := make(map[int]context.CancelFunc)
+	done := make(chan struct{})
+
+	// cancelOthers cancels every branch but winner.
+	cancelOthers := func(winner int) {
+		mu.Lock()
+		defer mu.Unlock()
+		for id, cancel := range cancels {
+			if id != winner {
+				cancel()
+			}
+		}
+	}
+
+	fire := func(id int) {
+		// getEndpoint mutates *i concurrently, so every read of *i,
+		// including the struct copy below, stays under mu.
+		mu.Lock()
+		ep, err := lb.getEndpoint(i, exclude)
+		var branch invocation.Invocation
+		var cancel context.CancelFunc
+		if err == nil {
+			exclude[ep] = struct{}{}
+			branch = *i
+			branch.Ctx, cancel = context.WithCancel(i.Ctx)
+			cancels[id] = cancel
+		}
+		mu.Unlock()
+		if err != nil {
+			once.Do(func() {
+				writeErr(err, cb)
+				close(done)
+			})
+			return
+		}
+
+		branch.Endpoint = ep
+		branchChain := *chain
+		branchChain.HandlerIndex = handlerIndex
+		branchChain.Next(&branch, func(r *invocation.Response) error {
+			once.Do(func() {
+				cancelOthers(id)
+				cb(r)
+				close(done)
+			})
+			return nil
+		})
+	}
+
+	go fire(0)
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		for n := 1; n < maxAttempts; n++ {
+			select {
+			case <-done:
+				return
+			case <-timer.C:
+				go fire(n)
+				timer.Reset(delay)
+			}
+		}
+	}()
+
+	<-done
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCancelOthersSkipsWinner checks a copy of the cancelOthers closure from
+// handleWithHedging (the closure is local to that function and cannot be
+// called from here): every branch but the winner is canceled, and the
+// winner is not.
+func TestCancelOthersSkipsWinner(t *testing.T) {
+	var mu sync.Mutex
+	canceled := map[int]bool{}
+	cancels := map[int]func(){
+		0: func() { canceled[0] = true },
+		1: func() { canceled[1] = true },
+		2: func() { canceled[2] = true },
+	}
+
+	cancelOthers := func(winner int) {
+		mu.Lock()
+		defer mu.Unlock()
+		for id, cancel := range cancels {
+			if id != winner {
+				cancel()
+			}
+		}
+	}
+
+	cancelOthers(1)
+
+	assert.True(t, canceled[0], "losing branch 0 should be canceled")
+	assert.False(t, canceled[1], "winning branch 1 must not cancel itself")
+	assert.True(t, canceled[2], "losing branch 2 should be canceled")
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cenkalti/backoff"
 	"github.com/go-chassis/go-chassis/client/rest"
@@ -13,13 +15,19 @@ import (
 	"github.com/go-chassis/go-chassis/core/invocation"
 	"github.com/go-chassis/go-chassis/core/lager"
 	"github.com/go-chassis/go-chassis/core/loadbalancer"
+	"github.com/go-chassis/go-chassis/core/registry"
 	"github.com/go-chassis/go-chassis/session"
 )
 
 // LBHandler loadbalancer handler struct
 type LBHandler struct{}
 
-func (lb *LBHandler) getEndpoint(i *invocation.Invocation) (string, error) {
+// maxExcludeAttempts bounds how many times getEndpoint will re-Pick to
+// avoid an excluded endpoint before giving up and returning whatever it
+// last picked.
+const maxExcludeAttempts = 5
+
+func (lb *LBHandler) getEndpoint(i *invocation.Invocation, exclude map[string]struct{}) (string, error) {
 	var strategyFun func() loadbalancer.Strategy
 	var err error
 	if i.Strategy == "" {
@@ -41,6 +49,28 @@ func (lb *LBHandler) getEndpoint(i *invocation.Invocation) (string, error) {
 		i.Filters = config.GetServerListFilters()
 	}
 
+	// An affinity rule set, when configured, takes the raw strategy as its
+	// tie-breaker and ranks candidates by weighted rule match instead.
+	if rules, rErr := config.GetAffinityRules(i.SourceMicroService, i.MicroServiceName); rErr == nil && len(rules) > 0 {
+		tieBreak := strategyFun
+		strategyFun = func() loadbalancer.Strategy {
+			return loadbalancer.NewAffinityStrategy(rules, tieBreak())
+		}
+	}
+
+	// A spread config, when configured, picks from the most underweight
+	// attribute bucket instead of whatever the base strategy would choose,
+	// using whatever strategyFun already is (including an affinity wrapping
+	// from above, if any) as its same-bucket tie-break so the two features
+	// compose instead of one clobbering the other.
+	if spreadCfg, ok := config.GetSpreadConfig(i.SourceMicroService, i.MicroServiceName); ok {
+		key := genKey(i.SourceServiceID, i.MicroServiceName)
+		tieBreak := strategyFun
+		strategyFun = func() loadbalancer.Strategy {
+			return loadbalancer.NewSpreadStrategy(key, spreadCfg.Attribute, spreadCfg.ToSpreadTarget(), spreadCfg.Window, tieBreak())
+		}
+	}
+
 	var sessionID string
 	if i.Strategy == loadbalancer.StrategySessionStickiness {
 		sessionID = getSessionID(i)
@@ -52,35 +82,81 @@ func (lb *LBHandler) getEndpoint(i *invocation.Invocation) (string, error) {
 		return "", err
 	}
 
-	ins, err := s.Pick()
-	if err != nil {
-		lbErr := loadbalancer.LBError{Message: err.Error()}
-		return "", lbErr
+	// resolveCandidate fills in i.Protocol on first use and resolves the
+	// instance's endpoint for it.
+	resolveCandidate := func(ins *registry.MicroServiceInstance) (string, error) {
+		if i.Protocol == "" {
+			i.Protocol = archaius.GetString("cse.references."+i.MicroServiceName+".transport", ins.DefaultProtocol)
+		}
+		if i.Protocol == "" {
+			for k := range ins.EndpointsMap {
+				i.Protocol = k
+				break
+			}
+		}
+		candidate, ok := ins.EndpointsMap[i.Protocol]
+		if !ok {
+			errStr := fmt.Sprintf("No available instance support ["+i.Protocol+"] protocol,"+
+				" msName: "+i.MicroServiceName+" %v", ins.EndpointsMap)
+			lbErr := loadbalancer.LBError{Message: errStr}
+			lager.Logger.Errorf(nil, lbErr.Error())
+			return "", lbErr
+		}
+		return candidate, nil
 	}
 
+	// Strategies whose Pick has an observable side effect (e.g. Spread's
+	// sliding-window recording) implement ExcludeAware so we can ask for an
+	// alternate pick directly, without ever recording a discarded
+	// candidate. Strategies without state (round-robin, random, ...) have
+	// no such risk, so they fall back to the simple retry loop below.
 	var ep string
-	if i.Protocol == "" {
-		i.Protocol = archaius.GetString("cse.references."+i.MicroServiceName+".transport", ins.DefaultProtocol)
-	}
-	if i.Protocol == "" {
-		for k := range ins.EndpointsMap {
-			i.Protocol = k
-			break
+	if aware, ok := s.(loadbalancer.ExcludeAware); ok && len(exclude) > 0 {
+		ins, err := aware.PickExcluding(i.Protocol, exclude)
+		if err != nil {
+			return "", loadbalancer.LBError{Message: err.Error()}
+		}
+		ep, err = resolveCandidate(ins)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		for attempt := 0; ; attempt++ {
+			ins, err := s.Pick()
+			if err != nil {
+				return "", loadbalancer.LBError{Message: err.Error()}
+			}
+			candidate, err := resolveCandidate(ins)
+			if err != nil {
+				return "", err
+			}
+			if _, excluded := exclude[candidate]; !excluded || attempt >= maxExcludeAttempts {
+				ep = candidate
+				break
+			}
 		}
 	}
-	ep, ok := ins.EndpointsMap[i.Protocol]
-	if !ok {
-		errStr := fmt.Sprintf("No available instance support ["+i.Protocol+"] protocol,"+
-			" msName: "+i.MicroServiceName+" %v", ins.EndpointsMap)
-		lbErr := loadbalancer.LBError{Message: errStr}
-		lager.Logger.Errorf(nil, lbErr.Error())
-		return "", lbErr
+
+	if mode := config.GetProxyProtocol(i.SourceMicroService, i.MicroServiceName); mode != "" && mode != proxyProtocolOff {
+		header, hErr := buildProxyProtocolHeaderFor(i, ep, mode)
+		if hErr != nil {
+			lager.Logger.Errorf(hErr, "failed to build PROXY protocol header for ["+ep+"]")
+		} else {
+			// rest.NewProxyProtocolTransport is the consumer: it writes
+			// whatever header is under this key to the raw connection
+			// before the request itself goes out.
+			i.Ctx = context.WithValue(i.Ctx, common.ProxyProtocolContextKey, header)
+		}
 	}
 	return ep, nil
 }
 
 // Handle to handle the load balancing
 func (lb *LBHandler) Handle(chain *Chain, i *invocation.Invocation, cb invocation.ResponseCallBack) {
+	if config.GetHedgeDelay(i.SourceMicroService, i.MicroServiceName) > 0 {
+		lb.handleWithHedging(chain, i, cb)
+		return
+	}
 	if !config.RetryEnabled(i.SourceMicroService, i.MicroServiceName) {
 		lb.handleWithNoRetry(chain, i, cb)
 	} else {
@@ -89,7 +165,7 @@ func (lb *LBHandler) Handle(chain *Chain, i *invocation.Invocation, cb invocatio
 }
 
 func (lb *LBHandler) handleWithNoRetry(chain *Chain, i *invocation.Invocation, cb invocation.ResponseCallBack) {
-	ep, err := lb.getEndpoint(i)
+	ep, err := lb.getEndpoint(i, nil)
 	if err != nil {
 		writeErr(err, cb)
 		return
@@ -103,10 +179,34 @@ func (lb *LBHandler) handleWithRetry(chain *Chain, i *invocation.Invocation, cb
 	retryOnSame := config.GetRetryOnSame(i.SourceMicroService, i.MicroServiceName)
 	retryOnNext := config.GetRetryOnNext(i.SourceMicroService, i.MicroServiceName)
 	handlerIndex := chain.HandlerIndex
+
+	// The retry budget bounds the whole call, on top of retryOnSame/
+	// retryOnNext: a deadline that abandons retrying once elapsed, and a
+	// shared token bucket per destination that caps what fraction of
+	// traffic may ever retry, so one bad destination can't make every
+	// caller multiplicatively retry.
+	budgetDuration, budgetRatio := config.GetRetryBudget(i.SourceMicroService, i.MicroServiceName)
+	bucket := retryBucketFor(genKey(i.SourceMicroService, i.MicroServiceName), budgetRatio)
+	bucket.depositForAttempt()
+	var deadline time.Time
+	if budgetDuration > 0 {
+		deadline = time.Now().Add(budgetDuration)
+	}
+
 	var invResp *invocation.Response
 	for j := 0; j < retryOnNext+1; j++ {
+		if j > 0 && !deadline.IsZero() && time.Now().After(deadline) {
+			// Retry deadline elapsed; abandon further attempts and return
+			// whatever the last attempt produced.
+			break
+		}
+		if j > 0 && !bucket.allowRetry() {
+			// Retry budget exhausted for this destination; return
+			// immediately rather than piling onto a struggling backend.
+			break
+		}
 		// exchange and retry on the next server
-		ep, err := lb.getEndpoint(i)
+		ep, err := lb.getEndpoint(i, nil)
 		if err != nil {
 			// if get endpoint failed, no need to retry
 			writeErr(err, cb)
@@ -119,6 +219,14 @@ func (lb *LBHandler) handleWithRetry(chain *Chain, i *invocation.Invocation, cb
 			if callTimes == retryOnSame+1 {
 				return backoff.Permanent(errors.New("retry times expires"))
 			}
+			if callTimes > 0 {
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					return backoff.Permanent(errors.New("retry deadline expired"))
+				}
+				if !bucket.allowRetry() {
+					return backoff.Permanent(errors.New("retry budget exhausted"))
+				}
+			}
 			callTimes++
 			i.Endpoint = ep
 			var respErr error
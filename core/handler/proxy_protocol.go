@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/go-chassis/go-chassis/core/common"
+	"github.com/go-chassis/go-chassis/core/invocation"
+)
+
+const (
+	proxyProtocolOff    = "off"
+	proxyProtocolSendV1 = "send-proxy"
+	proxyProtocolSendV2 = "send-proxy-v2"
+)
+
+// proxyProtocolSignatureV2 is the fixed 12-byte PROXY protocol v2 signature.
+var proxyProtocolSignatureV2 = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+// buildProxyProtocolHeaderFor resolves the client and destination addresses
+// for invocation i's chosen endpoint ep and serializes a PROXY protocol
+// header in mode (proxyProtocolSendV1 or proxyProtocolSendV2).
+func buildProxyProtocolHeaderFor(i *invocation.Invocation, ep, mode string) ([]byte, error) {
+	dstIP, dstPort, err := splitHostPortAddr(ep)
+	if err != nil {
+		return nil, err
+	}
+	srcIP, srcPort := resolveClientAddr(i)
+	return buildProxyProtocolHeader(mode, srcIP, dstIP, srcPort, dstPort)
+}
+
+// resolveClientAddr returns the original client address stashed on i.Ctx by
+// an inbound handler. When none was stashed, or it does not parse as
+// host:port, it returns 127.0.0.1 with port 0; the local address of the
+// outbound connection is not looked up.
+func resolveClientAddr(i *invocation.Invocation) (ip string, port int) {
+	if i.Ctx != nil {
+		if v := i.Ctx.Value(common.ClientAddrContextKey); v != nil {
+			if addr, ok := v.(string); ok {
+				if host, port, err := splitHostPortAddr(addr); err == nil {
+					return host, port
+				}
+			}
+		}
+	}
+	return "127.0.0.1", 0
+}
+
+func splitHostPortAddr(addr string) (string, int, error) {
+	addr = strings.TrimPrefix(addr, "http://")
+	addr = strings.TrimPrefix(addr, "https://")
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
+
+// buildProxyProtocolHeader serializes a PROXY protocol v1 or v2 header for
+// a TCP-over-IPv4/IPv6 connection from src to dst. mode must be
+// proxyProtocolSendV1 or proxyProtocolSendV2, and src and dst must be of the
+// same address family.
+func buildProxyProtocolHeader(mode, srcIP, dstIP string, srcPort, dstPort int) ([]byte, error) {
+	src := net.ParseIP(srcIP)
+	dst := net.ParseIP(dstIP)
+	if src == nil || dst == nil {
+		return nil, fmt.Errorf("invalid PROXY protocol address: src=%q dst=%q", srcIP, dstIP)
+	}
+	if (src.To4() == nil) != (dst.To4() == nil) {
+		return nil, fmt.Errorf("PROXY protocol addresses differ in family: src=%q dst=%q", srcIP, dstIP)
+	}
+
+	switch mode {
+	case proxyProtocolSendV1:
+		family := "TCP4"
+		if src.To4() == nil {
+			family = "TCP6"
+		}
+		return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", family, src.String(), dst.String(), srcPort, dstPort)), nil
+	case proxyProtocolSendV2:
+		var buf bytes.Buffer
+		buf.Write(proxyProtocolSignatureV2)
+		buf.WriteByte(0x21) // version 2, command PROXY
+
+		var addr []byte
+		if src4 := src.To4(); src4 != nil {
+			buf.WriteByte(0x11) // AF_INET << 4 | STREAM
+			addr = append(addr, src4...)
+			addr = append(addr, dst.To4()...)
+		} else {
+			buf.WriteByte(0x21) // AF_INET6 << 4 | STREAM
+			addr = append(addr, src.To16()...)
+			addr = append(addr, dst.To16()...)
+		}
+		ports := make([]byte, 4)
+		binary.BigEndian.PutUint16(ports[0:2], uint16(srcPort))
+		binary.BigEndian.PutUint16(ports[2:4], uint16(dstPort))
+		addr = append(addr, ports...)
+
+		length := make([]byte, 2)
+		binary.BigEndian.PutUint16(length, uint16(len(addr)))
+		buf.Write(length)
+		buf.Write(addr)
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol mode %q", mode)
+	}
+}
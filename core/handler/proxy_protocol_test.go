@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-chassis/go-chassis/client/rest"
+	"github.com/go-chassis/go-chassis/core/common"
+	"github.com/go-chassis/go-chassis/core/invocation"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProxyProtocolHeaderReachesTheWire drives the actual header-building
+// code getEndpoint calls (buildProxyProtocolHeaderFor), attaches it to a
+// context the same way getEndpoint does, then hands that context to
+// rest.NewProxyProtocolTransport, the real consumer in client/rest. It
+// stops short of going through LBHandler.getEndpoint/Handle and Chain.Next
+// themselves: Chain, the Strategy plugin registry, and BuildStrategy are
+// not part of this checkout, so there is no way to construct a runnable
+// chain here. This is the most end-to-end path available in this tree,
+// covering producer (core/handler) and consumer (client/rest) with the
+// real header bytes instead of a hand-built literal on either side.
+func TestProxyProtocolHeaderReachesTheWire(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, aerr := ln.Accept()
+		if aerr != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 256)
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _ := conn.Read(buf)
+		received <- buf[:n]
+	}()
+
+	i := &invocation.Invocation{Ctx: context.Background()}
+	i.Ctx = context.WithValue(i.Ctx, common.ClientAddrContextKey, "10.0.0.1:5555")
+	header, err := buildProxyProtocolHeaderFor(i, ln.Addr().String(), proxyProtocolSendV1)
+	assert.NoError(t, err)
+	i.Ctx = context.WithValue(i.Ctx, common.ProxyProtocolContextKey, header)
+
+	transport := rest.NewProxyProtocolTransport(nil)
+	conn, err := transport.DialContext(i.Ctx, "tcp", ln.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	select {
+	case got := <-received:
+		assert.Equal(t, header, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the PROXY protocol header")
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import "sync"
+
+// retryTokenBucket bounds how many retries a destination may spend relative
+// to the traffic it actually receives. Every non-retry attempt deposits
+// ratio tokens (capped at max); every retry withdraws one.
+type retryTokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	max    float64
+	ratio  float64
+}
+
+func newRetryTokenBucket(ratio float64) *retryTokenBucket {
+	max := ratio * 10
+	if max < 1 {
+		max = 1
+	}
+	return &retryTokenBucket{tokens: max, max: max, ratio: ratio}
+}
+
+// depositForAttempt credits the bucket for one new top-level request.
+func (b *retryTokenBucket) depositForAttempt() {
+	if b.ratio <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.max {
+		b.tokens = b.max
+	}
+}
+
+// allowRetry reports whether the bucket has spare capacity for a retry and,
+// if so, spends one token. A non-positive ratio means the budget is
+// disabled and retries are always allowed.
+func (b *retryTokenBucket) allowRetry() bool {
+	if b.ratio <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// updateRatio refreshes ratio and max from the latest config value, clamping
+// tokens to the new max but otherwise leaving accrued budget alone.
+func (b *retryTokenBucket) updateRatio(ratio float64) {
+	max := ratio * 10
+	if max < 1 {
+		max = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ratio = ratio
+	b.max = max
+	if b.tokens > b.max {
+		b.tokens = b.max
+	}
+}
+
+// retryBuckets holds one shared retryTokenBucket per destination, keyed by
+// genKey(SourceMicroService, MicroServiceName), so every call from the same
+// source service to the same destination draws from the same budget.
+var retryBuckets sync.Map
+
+func retryBucketFor(key string, ratio float64) *retryTokenBucket {
+	if v, ok := retryBuckets.Load(key); ok {
+		b := v.(*retryTokenBucket)
+		b.updateRatio(ratio)
+		return b
+	}
+	b := newRetryTokenBucket(ratio)
+	actual, _ := retryBuckets.LoadOrStore(key, b)
+	if actual != b {
+		actual.(*retryTokenBucket).updateRatio(ratio)
+	}
+	return actual.(*retryTokenBucket)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTokenBucketAllowsRoughlyRatioShareOfRetries(t *testing.T) {
+	b := newRetryTokenBucket(0.1)
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		b.depositForAttempt()
+		if b.allowRetry() {
+			allowed++
+		}
+	}
+	assert.Less(t, allowed, 20, "a 0.1 ratio must not allow every retry")
+}
+
+func TestRetryTokenBucketDisabledRatioAlwaysAllows(t *testing.T) {
+	b := newRetryTokenBucket(0)
+	for i := 0; i < 5; i++ {
+		assert.True(t, b.allowRetry())
+	}
+}
+
+func TestRetryBucketForRefreshesRatioOnExistingBucket(t *testing.T) {
+	key := "TestRetryBucketForRefreshesRatioOnExistingBucket"
+	b := retryBucketFor(key, 0.1)
+	assert.InDelta(t, 1.0, b.max, 0.0001)
+
+	updated := retryBucketFor(key, 1.0)
+	assert.Same(t, b, updated, "the same destination must keep sharing one bucket")
+	assert.InDelta(t, 10.0, updated.max, 0.0001, "a config change must take effect on the existing bucket")
+}
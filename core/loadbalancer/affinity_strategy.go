@@ -0,0 +1,151 @@
+package loadbalancer
+
+import (
+	"sort"
+
+	"github.com/go-chassis/go-chassis/core/invocation"
+	"github.com/go-chassis/go-chassis/core/registry"
+)
+
+// StrategyAffinity identifies the affinity wrapping behavior for logging and
+// config lookups; it is not registered with GetStrategyPlugin.
+const StrategyAffinity = "Affinity"
+
+// AffinityRule biases instance selection by Weight on a Key/Value metadata
+// match; a Required rule hard-filters non-matching instances instead.
+type AffinityRule struct {
+	Key      string `yaml:"key" json:"key"`
+	Value    string `yaml:"value" json:"value"`
+	Weight   int    `yaml:"weight" json:"weight"`
+	Required bool   `yaml:"required" json:"required"`
+}
+
+// AffinityStrategy implements Strategy by scoring candidates against Rules
+// and returning the top scorer, breaking ties via tieBreak.
+type AffinityStrategy struct {
+	Rules     []AffinityRule
+	tieBreak  Strategy
+	instances []*registry.MicroServiceInstance
+	inv       *invocation.Invocation
+	svcName   string
+}
+
+// NewAffinityStrategy builds an AffinityStrategy; tieBreak may be nil, in
+// which case the first tied instance is returned.
+func NewAffinityStrategy(rules []AffinityRule, tieBreak Strategy) *AffinityStrategy {
+	return &AffinityStrategy{Rules: rules, tieBreak: tieBreak}
+}
+
+// ReceiveData caches the instance pool for the next Pick call and forwards
+// it to the tie-break strategy so it stays in sync.
+func (a *AffinityStrategy) ReceiveData(inv *invocation.Invocation, instances []*registry.MicroServiceInstance, serviceName string) {
+	a.instances = instances
+	a.inv = inv
+	a.svcName = serviceName
+	if a.tieBreak != nil {
+		a.tieBreak.ReceiveData(inv, instances, serviceName)
+	}
+}
+
+// Pick filters out instances that fail a required rule, scores the rest,
+// and returns the highest scoring instance, breaking ties via tieBreak.
+func (a *AffinityStrategy) Pick() (*registry.MicroServiceInstance, error) {
+	candidates := a.filterRequired(a.instances)
+	if len(candidates) == 0 {
+		return nil, LBError{Message: "no instance satisfies the required affinity rules"}
+	}
+
+	scores := make(map[string]int, len(candidates))
+	for _, ins := range candidates {
+		scores[ins.InstanceID] = a.score(ins)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i].InstanceID] > scores[candidates[j].InstanceID]
+	})
+
+	top := scores[candidates[0].InstanceID]
+	tied := candidates[:1]
+	for _, ins := range candidates[1:] {
+		if scores[ins.InstanceID] != top {
+			break
+		}
+		tied = append(tied, ins)
+	}
+	if len(tied) == 1 || a.tieBreak == nil {
+		return tied[0], nil
+	}
+	a.tieBreak.ReceiveData(a.inv, tied, a.svcName)
+	return a.tieBreak.Pick()
+}
+
+// PickExcluding implements ExcludeAware by filtering out already-excluded
+// endpoints before scoring.
+func (a *AffinityStrategy) PickExcluding(protocol string, excludedEndpoints map[string]struct{}) (*registry.MicroServiceInstance, error) {
+	if len(excludedEndpoints) == 0 {
+		return a.Pick()
+	}
+
+	original := a.instances
+	filtered := make([]*registry.MicroServiceInstance, 0, len(original))
+	for _, ins := range original {
+		ep := ins.EndpointsMap[protocol]
+		if ep == "" {
+			ep = ins.EndpointsMap[ins.DefaultProtocol]
+		}
+		if _, skip := excludedEndpoints[ep]; !skip {
+			filtered = append(filtered, ins)
+		}
+	}
+	if len(filtered) == 0 {
+		// Every instance is excluded; fall back to the full pool rather
+		// than failing the call outright.
+		filtered = original
+	}
+
+	a.instances = filtered
+	defer func() { a.instances = original }()
+	return a.Pick()
+}
+
+// filterRequired drops every instance that fails at least one Required rule.
+func (a *AffinityStrategy) filterRequired(instances []*registry.MicroServiceInstance) []*registry.MicroServiceInstance {
+	required := make([]AffinityRule, 0, len(a.Rules))
+	for _, r := range a.Rules {
+		if r.Required {
+			required = append(required, r)
+		}
+	}
+	if len(required) == 0 {
+		return instances
+	}
+
+	kept := make([]*registry.MicroServiceInstance, 0, len(instances))
+	for _, ins := range instances {
+		ok := true
+		for _, r := range required {
+			if ins.Metadata[r.Key] != r.Value {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, ins)
+		}
+	}
+	return kept
+}
+
+// score sums the weight of every non-required rule the instance matches.
+func (a *AffinityStrategy) score(ins *registry.MicroServiceInstance) int {
+	total := 0
+	for _, r := range a.Rules {
+		if r.Required {
+			continue
+		}
+		if ins.Metadata[r.Key] == r.Value {
+			total += r.Weight
+		}
+	}
+	return total
+}
+
@@ -0,0 +1,89 @@
+package loadbalancer
+
+import (
+	"testing"
+
+	"github.com/go-chassis/go-chassis/core/invocation"
+	"github.com/go-chassis/go-chassis/core/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// capturingStub is a Strategy that records the inv/serviceName it was given,
+// so tests can assert AffinityStrategy forwards them to its tie-break
+// instead of the placeholder nil/"" it used to pass.
+type capturingStub struct {
+	gotInv     *invocation.Invocation
+	gotSvcName string
+	instances  []*registry.MicroServiceInstance
+}
+
+func (c *capturingStub) ReceiveData(inv *invocation.Invocation, instances []*registry.MicroServiceInstance, serviceName string) {
+	c.gotInv = inv
+	c.gotSvcName = serviceName
+	c.instances = instances
+}
+
+func (c *capturingStub) Pick() (*registry.MicroServiceInstance, error) {
+	return c.instances[0], nil
+}
+
+func TestAffinityStrategyFiltersRequired(t *testing.T) {
+	rules := []AffinityRule{{Key: "datacenter", Value: "z1", Required: true}}
+	s := NewAffinityStrategy(rules, nil)
+	s.ReceiveData(nil, []*registry.MicroServiceInstance{
+		dcInstance("a", "z2"),
+		dcInstance("b", "z1"),
+	}, "dest")
+
+	ins, err := s.Pick()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", ins.InstanceID)
+}
+
+func TestAffinityStrategyRequiredExcludesAll(t *testing.T) {
+	rules := []AffinityRule{{Key: "zone", Value: "z9", Required: true}}
+	s := NewAffinityStrategy(rules, nil)
+	s.ReceiveData(nil, []*registry.MicroServiceInstance{dcInstance("a", "z1")}, "dest")
+
+	_, err := s.Pick()
+	assert.Error(t, err)
+}
+
+func TestAffinityStrategyTieBreakReceivesRealInvAndServiceName(t *testing.T) {
+	rules := []AffinityRule{{Key: "zone", Value: "z1", Weight: 1}}
+	stub := &capturingStub{}
+	s := NewAffinityStrategy(rules, stub)
+	inv := &invocation.Invocation{MicroServiceName: "dest"}
+	s.ReceiveData(inv, []*registry.MicroServiceInstance{
+		dcInstance("a", "z1"),
+		dcInstance("b", "z1"),
+	}, "dest")
+
+	_, err := s.Pick()
+	assert.NoError(t, err)
+	assert.Same(t, inv, stub.gotInv, "tie-break must receive the real invocation, not nil")
+	assert.Equal(t, "dest", stub.gotSvcName, "tie-break must receive the real service name, not \"\"")
+}
+
+func TestAffinityStrategyPickExcludingSkipsExcludedEndpoints(t *testing.T) {
+	rules := []AffinityRule{{Key: "zone", Value: "z1", Weight: 1}}
+	s := NewAffinityStrategy(rules, nil)
+	a := dcInstance("a", "z1")
+	b := dcInstance("b", "z1")
+	s.ReceiveData(nil, []*registry.MicroServiceInstance{a, b}, "dest")
+
+	ins, err := s.PickExcluding("rest", map[string]struct{}{"a:8080": {}})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", ins.InstanceID)
+}
+
+func TestAffinityStrategyPickExcludingFallsBackWhenAllExcluded(t *testing.T) {
+	rules := []AffinityRule{{Key: "zone", Value: "z1", Weight: 1}}
+	s := NewAffinityStrategy(rules, nil)
+	a := dcInstance("a", "z1")
+	s.ReceiveData(nil, []*registry.MicroServiceInstance{a}, "dest")
+
+	ins, err := s.PickExcluding("rest", map[string]struct{}{"a:8080": {}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", ins.InstanceID, "with every instance excluded, fall back to the full pool")
+}
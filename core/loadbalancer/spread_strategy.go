@@ -0,0 +1,271 @@
+package loadbalancer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-chassis/go-chassis/core/invocation"
+	"github.com/go-chassis/go-chassis/core/registry"
+)
+
+// StrategySpread identifies the spread wrapping behavior for logging and
+// config lookups; it is not registered with GetStrategyPlugin.
+const StrategySpread = "Spread"
+
+// defaultSpreadWindow bounds how far back observed picks are remembered
+// when a caller does not configure its own window.
+const defaultSpreadWindow = time.Minute
+
+// SpreadTarget maps an attribute value, e.g. a datacenter name, to the
+// fraction of picks that should land on instances carrying that value.
+// Targets do not need to sum to 1; they are normalized at selection time.
+type SpreadTarget map[string]float64
+
+// spreadPick is one observed selection, recorded so it can age out of the
+// sliding window.
+type spreadPick struct {
+	value string
+	at    time.Time
+}
+
+// spreadState is the sliding-window bookkeeping for a single
+// SourceServiceID+MicroServiceName pair, shared by every SpreadStrategy
+// instance built for that pair.
+type spreadState struct {
+	mu     sync.Mutex
+	window time.Duration
+	picks  []spreadPick
+}
+
+func (s *spreadState) record(value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timeNow()
+	s.picks = append(s.picks, spreadPick{value: value, at: now})
+	s.evict(now)
+}
+
+func (s *spreadState) counts(now time.Time) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now)
+	counts := make(map[string]int, len(s.picks))
+	for _, p := range s.picks {
+		counts[p.value]++
+	}
+	return counts
+}
+
+func (s *spreadState) evict(now time.Time) {
+	if s.window <= 0 {
+		return
+	}
+	cutoff := now.Add(-s.window)
+	i := 0
+	for i < len(s.picks) && s.picks[i].at.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		s.picks = s.picks[i:]
+	}
+}
+
+// timeNow is a var so tests can stub it; production code always uses
+// time.Now.
+var timeNow = time.Now
+
+var spreadStates sync.Map // key: SourceServiceID+"."+MicroServiceName -> *spreadState
+
+// spreadStateFor returns the shared sliding-window state for a
+// source/destination pair, creating it on first use.
+func spreadStateFor(key string, window time.Duration) *spreadState {
+	if window <= 0 {
+		window = defaultSpreadWindow
+	}
+	v, _ := spreadStates.LoadOrStore(key, &spreadState{window: window})
+	return v.(*spreadState)
+}
+
+// SpreadStrategy picks the instance whose attribute value is most
+// underweight relative to Targets, given what the sliding window has
+// actually picked, breaking same-bucket ties via tieBreak.
+type SpreadStrategy struct {
+	Attribute string
+	Targets   SpreadTarget
+	tieBreak  Strategy
+	state     *spreadState
+	inv       *invocation.Invocation
+	svcName   string
+	instances []*registry.MicroServiceInstance
+}
+
+// NewSpreadStrategy builds a SpreadStrategy for the source/dest key; targets
+// nil/empty means spread evenly, window <=0 means defaultSpreadWindow, and
+// tieBreak nil returns the first instance in bucket order.
+func NewSpreadStrategy(key, attribute string, targets SpreadTarget, window time.Duration, tieBreak Strategy) *SpreadStrategy {
+	return &SpreadStrategy{
+		Attribute: attribute,
+		Targets:   targets,
+		tieBreak:  tieBreak,
+		state:     spreadStateFor(key, window),
+	}
+}
+
+// ReceiveData caches the instance pool, inv and serviceName for the next
+// Pick call, which forwards them to the tie-break strategy with only the
+// winning bucket's instances.
+func (s *SpreadStrategy) ReceiveData(inv *invocation.Invocation, instances []*registry.MicroServiceInstance, serviceName string) {
+	s.inv = inv
+	s.svcName = serviceName
+	s.instances = instances
+}
+
+// Pick returns an instance from the most underweight attribute bucket,
+// breaking ties via tieBreak, and records the choice so future Picks stay
+// balanced.
+func (s *SpreadStrategy) Pick() (*registry.MicroServiceInstance, error) {
+	if len(s.instances) == 0 {
+		return nil, LBError{Message: "no instance available for spread strategy"}
+	}
+
+	targets := normalizeTargets(s.Targets)
+	if len(targets) == 0 {
+		targets = evenTargets(s.instances, s.Attribute)
+	}
+
+	observed := s.state.counts(timeNow())
+	total := 0
+	for _, c := range observed {
+		total += c
+	}
+
+	present := make(map[string]bool, len(s.instances))
+	for _, ins := range s.instances {
+		present[ins.Metadata[s.Attribute]] = true
+	}
+
+	bestValue := ""
+	bestDeficit := 0.0
+	haveBest := false
+	for value, target := range targets {
+		if !present[value] {
+			continue
+		}
+		deficit := target - ratio(observed[value], total)
+		if !haveBest || deficit > bestDeficit {
+			bestValue, bestDeficit, haveBest = value, deficit, true
+		}
+	}
+
+	var bucket []*registry.MicroServiceInstance
+	if haveBest {
+		for _, ins := range s.instances {
+			if ins.Metadata[s.Attribute] == bestValue {
+				bucket = append(bucket, ins)
+			}
+		}
+	}
+	if len(bucket) == 0 {
+		// Nothing matched a target bucket; fall back to the full pool so
+		// the pair is never left completely unserved.
+		bucket = s.instances
+	}
+
+	winner := bucket[0]
+	if len(bucket) > 1 && s.tieBreak != nil {
+		s.tieBreak.ReceiveData(s.inv, bucket, s.svcName)
+		if picked, err := s.tieBreak.Pick(); err == nil {
+			winner = picked
+		}
+	}
+
+	s.state.record(winner.Metadata[s.Attribute])
+	return winner, nil
+}
+
+// ExcludeAware is implemented by strategies whose Pick has an observable
+// side effect a discarded candidate must not trigger. Callers retrying
+// against already-in-flight endpoints, like hedging, should prefer
+// PickExcluding over repeatedly calling Pick.
+type ExcludeAware interface {
+	// PickExcluding behaves like Pick but does not consider, and so does
+	// not record, any instance whose endpoint under protocol is excluded.
+	// When every instance is excluded, the implementations in this package
+	// pick from the full pool instead.
+	PickExcluding(protocol string, excludedEndpoints map[string]struct{}) (*registry.MicroServiceInstance, error)
+}
+
+// PickExcluding implements ExcludeAware by filtering out already-excluded
+// endpoints before ever calling Pick.
+func (s *SpreadStrategy) PickExcluding(protocol string, excludedEndpoints map[string]struct{}) (*registry.MicroServiceInstance, error) {
+	if len(excludedEndpoints) == 0 {
+		return s.Pick()
+	}
+
+	original := s.instances
+	filtered := make([]*registry.MicroServiceInstance, 0, len(original))
+	for _, ins := range original {
+		ep := ins.EndpointsMap[protocol]
+		if ep == "" {
+			ep = ins.EndpointsMap[ins.DefaultProtocol]
+		}
+		if _, skip := excludedEndpoints[ep]; !skip {
+			filtered = append(filtered, ins)
+		}
+	}
+	if len(filtered) == 0 {
+		// Every instance is excluded; fall back to the full pool rather
+		// than failing the call outright.
+		filtered = original
+	}
+
+	s.instances = filtered
+	defer func() { s.instances = original }()
+	return s.Pick()
+}
+
+func ratio(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// normalizeTargets rescales targets so its values sum to 1, so operators
+// can configure fractions or percentages (e.g. {dc1: 60, dc2: 30, dc3: 10})
+// interchangeably. A nil or all-zero input returns nil.
+func normalizeTargets(t SpreadTarget) SpreadTarget {
+	if len(t) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, v := range t {
+		sum += v
+	}
+	if sum <= 0 {
+		return nil
+	}
+	normalized := make(SpreadTarget, len(t))
+	for k, v := range t {
+		normalized[k] = v / sum
+	}
+	return normalized
+}
+
+// evenTargets builds a uniform SpreadTarget over every attribute value
+// observed among instances.
+func evenTargets(instances []*registry.MicroServiceInstance, attribute string) SpreadTarget {
+	values := map[string]struct{}{}
+	for _, ins := range instances {
+		values[ins.Metadata[attribute]] = struct{}{}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	share := 1.0 / float64(len(values))
+	targets := make(SpreadTarget, len(values))
+	for v := range values {
+		targets[v] = share
+	}
+	return targets
+}
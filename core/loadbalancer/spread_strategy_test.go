@@ -0,0 +1,85 @@
+package loadbalancer
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/go-chassis/go-chassis/core/invocation"
+	"github.com/go-chassis/go-chassis/core/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func dcInstance(id, dc string) *registry.MicroServiceInstance {
+	return &registry.MicroServiceInstance{
+		InstanceID:      id,
+		DefaultProtocol: "rest",
+		EndpointsMap:    map[string]string{"rest": id + ":8080"},
+		Metadata:        map[string]string{"datacenter": dc},
+	}
+}
+
+// TestNormalizeTargetsAcceptsPercentages matches the literal example from
+// the backlog request (60/30/10 rather than 0.6/0.3/0.1) and checks it
+// normalizes to fractions summing to 1 instead of producing raw deficits
+// outside [-1, 1].
+func TestNormalizeTargetsAcceptsPercentages(t *testing.T) {
+	normalized := normalizeTargets(SpreadTarget{"dc1": 60, "dc2": 30, "dc3": 10})
+
+	sum := 0.0
+	for _, v := range normalized {
+		sum += v
+	}
+	assert.InDelta(t, 1.0, sum, 0.0001)
+	assert.InDelta(t, 0.6, normalized["dc1"], 0.0001)
+}
+
+func TestNormalizeTargetsEmptyOrZero(t *testing.T) {
+	assert.Nil(t, normalizeTargets(nil))
+	assert.Nil(t, normalizeTargets(SpreadTarget{"dc1": 0, "dc2": 0}))
+}
+
+// roundRobinStub is a minimal Strategy used only to exercise
+// SpreadStrategy's tie-break delegation in tests.
+type roundRobinStub struct {
+	mu        sync.Mutex
+	instances []*registry.MicroServiceInstance
+	next      int
+}
+
+func (r *roundRobinStub) ReceiveData(inv *invocation.Invocation, instances []*registry.MicroServiceInstance, serviceName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances = instances
+}
+
+func (r *roundRobinStub) Pick() (*registry.MicroServiceInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.instances) == 0 {
+		return nil, LBError{Message: "no instances"}
+	}
+	ins := r.instances[r.next%len(r.instances)]
+	r.next++
+	return ins, nil
+}
+
+// TestSpreadStrategyTieBreakDistributesWithinBucket verifies that, when
+// several instances share the winning bucket, repeated Picks don't always
+// return the same one — the tie-break strategy gets to decide.
+func TestSpreadStrategyTieBreakDistributesWithinBucket(t *testing.T) {
+	instances := []*registry.MicroServiceInstance{
+		dcInstance("a", "dc1"),
+		dcInstance("b", "dc1"),
+	}
+	tieBreak := &roundRobinStub{}
+	s := NewSpreadStrategy("src.dest-tiebreak-"+t.Name(), "datacenter", SpreadTarget{"dc1": 1}, 0, tieBreak)
+
+	seen := map[string]bool{}
+	for n := 0; n < 4; n++ {
+		s.ReceiveData(nil, instances, "dest")
+		ins, err := s.Pick()
+		assert.NoError(t, err)
+		seen[ins.InstanceID] = true
+	}
+	assert.Len(t, seen, 2, "both same-bucket instances should be reachable via the tie-break")
+}